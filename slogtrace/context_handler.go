@@ -0,0 +1,103 @@
+package slogtrace
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+)
+
+// ContextHandler wraps a slog.Handler, tagging every record with the
+// trace_id/span_id of the active span in the record's context. It is the
+// mirror image of Exporter: where Exporter turns spans into log records,
+// ContextHandler can turn log records into span events.
+type ContextHandler struct {
+	inner            slog.Handler
+	recordAsEvent    bool
+	minLevelForEvent slog.Level
+}
+
+// ContextHandlerOption configures a ContextHandler. Options are applied in
+// the order they are passed to NewContextHandler.
+type ContextHandlerOption func(*ContextHandler)
+
+// WithRecordAsEvent controls whether each handled record is also added as
+// an event on the active span, via span.AddEvent. Defaults to false.
+func WithRecordAsEvent(recordAsEvent bool) ContextHandlerOption {
+	return func(h *ContextHandler) {
+		h.recordAsEvent = recordAsEvent
+	}
+}
+
+// WithMinLevelForEvent sets the minimum record level that gets added as a
+// span event when WithRecordAsEvent is enabled. Defaults to slog.LevelInfo.
+func WithMinLevelForEvent(level slog.Level) ContextHandlerOption {
+	return func(h *ContextHandler) {
+		h.minLevelForEvent = level
+	}
+}
+
+// NewContextHandler returns a slog.Handler that delegates to inner, after
+// attaching the trace_id/span_id of the span active in the record's
+// context, if any.
+func NewContextHandler(inner slog.Handler, opts ...ContextHandlerOption) slog.Handler {
+	h := &ContextHandler{
+		inner:            inner,
+		minLevelForEvent: slog.LevelInfo,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		if h.recordAsEvent && record.Level >= h.minLevelForEvent {
+			// Build the event from the record's own attributes, before the
+			// trace_id/span_id below are added, so the event on this span
+			// isn't tagged with its own span's IDs.
+			addRecordAsEvent(trace.SpanFromContext(ctx), record)
+		}
+
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+
+	return h.inner.Handle(ctx, record)
+}
+
+func addRecordAsEvent(span trace.Span, record slog.Record) {
+	attrs := make([]attribute.KeyValue, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attribute.String(attr.Key, attr.Value.String()))
+		return true
+	})
+
+	span.AddEvent(record.Message, trace.WithAttributes(attrs...))
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{
+		inner:            h.inner.WithAttrs(attrs),
+		recordAsEvent:    h.recordAsEvent,
+		minLevelForEvent: h.minLevelForEvent,
+	}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{
+		inner:            h.inner.WithGroup(name),
+		recordAsEvent:    h.recordAsEvent,
+		minLevelForEvent: h.minLevelForEvent,
+	}
+}
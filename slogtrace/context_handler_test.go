@@ -0,0 +1,123 @@
+package slogtrace_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/subnova/slog-exporter/slogtrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/exp/slog"
+	"testing"
+	"time"
+)
+
+func TestContextHandlerAddsSpanContext(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	inner := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(slogtrace.NewContextHandler(inner))
+
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	tracer := tracerProvider.Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "test")
+	defer span.End()
+
+	logger.InfoContext(ctx, "handling request")
+	_ = w.Flush()
+
+	var data map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := span.SpanContext()
+	if data["trace_id"] != sc.TraceID().String() {
+		t.Errorf("expected trace_id to be %v, got %v", sc.TraceID().String(), data["trace_id"])
+	}
+	if data["span_id"] != sc.SpanID().String() {
+		t.Errorf("expected span_id to be %v, got %v", sc.SpanID().String(), data["span_id"])
+	}
+}
+
+func TestContextHandlerWithoutSpanPassesThrough(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	inner := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(slogtrace.NewContextHandler(inner))
+
+	logger.InfoContext(context.Background(), "no span here")
+	_ = w.Flush()
+
+	var data map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := data["trace_id"]; ok {
+		t.Errorf("expected no trace_id without an active span, got %v", data["trace_id"])
+	}
+}
+
+func TestContextHandlerRecordsAsSpanEvents(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	inner := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(slogtrace.NewContextHandler(inner, slogtrace.WithRecordAsEvent(true)))
+
+	traceExporter, err := slogtrace.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exportBuf := bytes.Buffer{}
+	exportW := bufio.NewWriter(&exportBuf)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(exportW, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	tracer := tracerProvider.Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "test")
+	logger.InfoContext(ctx, "handling request", slog.String("key", "value"))
+	span.End()
+
+	_ = tracerProvider.Shutdown(context.Background())
+	_ = exportW.Flush()
+
+	var lines []map[string]any
+	for _, line := range bytes.Split(exportBuf.Bytes(), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var row map[string]any
+		if err := json.Unmarshal(line, &row); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, row)
+	}
+
+	found := false
+	for _, line := range lines {
+		if line["msg"] == "handling request" {
+			found = true
+			if line["key"] != "value" {
+				t.Errorf("expected event attribute key to be value, got %v", line["key"])
+			}
+			if _, ok := line["trace_id"]; ok {
+				t.Errorf("expected event not to be tagged with its own span's trace_id, got %v", line["trace_id"])
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected the logged record to be emitted as a span event, got %v", lines)
+	}
+}
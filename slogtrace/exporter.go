@@ -1,26 +1,50 @@
 package slogtrace
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
-	"go.opentelemetry.io/otel/sdk/trace"
-	"golang.org/x/exp/slices"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"golang.org/x/exp/slog"
+	"strconv"
+	"strings"
 	"sync"
 )
 
 type Exporter struct {
 	stoppedMu sync.RWMutex
 	stopped   bool
+
+	keyMapper                 KeyMapper
+	includeSpanContext        bool
+	includeResourceAttributes bool
+	attributeFilter           attribute.Filter
+	arrayEncoding             ArrayEncoding
+	eventLevelFunc            EventLevelFunc
+	eventFilter               func(sdktrace.Event) bool
+	ordering                  Ordering
+	logger                    *slog.Logger
+	handler                   slog.Handler
+	baseAttrs                 []slog.Attr
 }
 
-func New() (*Exporter, error) {
-	return &Exporter{}, nil
+func New(opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		keyMapper:                 defaultKeyMapper,
+		includeSpanContext:        true,
+		includeResourceAttributes: true,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e, nil
 }
 
-func (e *Exporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+func (e *Exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
 	e.stoppedMu.RLock()
 	stopped := e.stopped
 	e.stoppedMu.RUnlock()
@@ -32,57 +56,211 @@ func (e *Exporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan)
 		return nil
 	}
 
-	var records []slog.Record
+	handler := e.resolveHandler()
 
+	if e.ordering == Unordered {
+		for _, span := range spans {
+			for _, record := range e.buildSpanRecords(ctx, handler, span) {
+				if err := handler.Handle(ctx, record); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	cursors := make(cursorHeap, 0, len(spans))
 	for _, span := range spans {
-		var level = slog.LevelInfo
-		if span.Status().Code == codes.Error {
-			level = slog.LevelError
+		if records := e.buildSpanRecords(ctx, handler, span); len(records) > 0 {
+			cursors = append(cursors, &spanCursor{records: records})
+		}
+	}
+	heap.Init(&cursors)
+
+	for cursors.Len() > 0 {
+		c := cursors[0]
+		record := c.records[c.pos]
+
+		if err := handler.Handle(ctx, record); err != nil {
+			return err
 		}
-		record := slog.NewRecord(span.StartTime(), level, span.Name(), 0)
 
-		duration := span.EndTime().Sub(span.StartTime())
+		c.pos++
+		if c.pos < len(c.records) {
+			heap.Fix(&cursors, 0)
+		} else {
+			heap.Pop(&cursors)
+		}
+	}
+
+	return nil
+}
+
+// buildSpanRecords converts a single span, and the events within it that
+// survive the configured filter, level function, and the handler's level
+// threshold, into a time-ordered slice of records: the span record itself
+// followed by its event records.
+func (e *Exporter) buildSpanRecords(ctx context.Context, handler slog.Handler, span sdktrace.ReadOnlySpan) []slog.Record {
+	var level = slog.LevelInfo
+	if span.Status().Code == codes.Error {
+		level = slog.LevelError
+	}
+
+	spanAttrs := e.spanContextAttrs(span)
 
-		var attrs []slog.Attr
-		attrs = append(attrs, slog.String("duration", duration.String()))
-		attrs = append(attrs, attributesToAttrs(span.Attributes())...)
+	record := slog.NewRecord(span.StartTime(), level, span.Name(), 0)
 
-		record.AddAttrs(attrs...)
+	duration := span.EndTime().Sub(span.StartTime())
 
-		records = append(records, record)
+	var attrs []slog.Attr
+	attrs = append(attrs, e.baseAttrs...)
+	attrs = append(attrs, slog.String("duration", duration.String()))
+	attrs = append(attrs, spanAttrs...)
+	attrs = append(attrs, e.attributesToAttrs(span.Attributes())...)
+
+	record.AddAttrs(attrs...)
+
+	records := []slog.Record{record}
 
-		for _, event := range span.Events() {
-			eventRecord := slog.NewRecord(event.Time, level, event.Name, 0)
-			eventRecord.AddAttrs(attributesToAttrs(event.Attributes)...)
+	for _, event := range span.Events() {
+		if e.eventFilter != nil && !e.eventFilter(event) {
+			continue
+		}
+
+		eventLevel := level
+		if e.eventLevelFunc != nil {
+			if eventLevel = e.eventLevelFunc(event); eventLevel == LevelSkip {
+				continue
+			}
+		}
 
-			records = append(records, eventRecord)
+		if !handler.Enabled(ctx, eventLevel) {
+			continue
 		}
+
+		eventRecord := slog.NewRecord(event.Time, eventLevel, event.Name, 0)
+
+		rest, exceptionAttrs := splitExceptionAttributes(event.Attributes)
+
+		var eventAttrs []slog.Attr
+		eventAttrs = append(eventAttrs, e.baseAttrs...)
+		eventAttrs = append(eventAttrs, spanAttrs...)
+		eventAttrs = append(eventAttrs, e.attributesToAttrs(rest)...)
+		if attr, ok := e.exceptionGroupAttr(exceptionAttrs); ok {
+			eventAttrs = append(eventAttrs, attr)
+		}
+
+		eventRecord.AddAttrs(eventAttrs...)
+
+		records = append(records, eventRecord)
+	}
+
+	return records
+}
+
+// resolveHandler picks the slog.Handler records are emitted to: an
+// explicit WithHandler takes precedence over WithLogger's handler, which
+// in turn takes precedence over slog.Default().
+func (e *Exporter) resolveHandler() slog.Handler {
+	if e.handler != nil {
+		return e.handler
+	}
+	if e.logger != nil {
+		return e.logger.Handler()
 	}
+	return slog.Default().Handler()
+}
+
+// spanCursor walks the time-ordered records produced by a single span: the
+// span record itself, then its events in order.
+type spanCursor struct {
+	records []slog.Record
+	pos     int
+}
+
+// cursorHeap is a container/heap of spanCursors ordered by the Time of each
+// cursor's current record, used to merge per-span record streams into a
+// single time-ordered stream across the whole batch without sorting it.
+type cursorHeap []*spanCursor
+
+func (h cursorHeap) Len() int { return len(h) }
 
-	slices.SortStableFunc(records, func(a, b slog.Record) int {
-		if a.Time == b.Time {
-			return 0
+func (h cursorHeap) Less(i, j int) bool {
+	return h[i].records[h[i].pos].Time.Before(h[j].records[h[j].pos].Time)
+}
+
+func (h cursorHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *cursorHeap) Push(x any) {
+	*h = append(*h, x.(*spanCursor))
+}
+
+func (h *cursorHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// spanContextAttrs builds the "span" and "resource" groups shared by a
+// span's own record and every event record it produces, so that log
+// pipelines can correlate both with the originating trace.
+func (e *Exporter) spanContextAttrs(span sdktrace.ReadOnlySpan) []slog.Attr {
+	var groups []slog.Attr
+
+	if e.includeSpanContext {
+		spanCtx := span.SpanContext()
+
+		fields := []any{
+			slog.String(e.keyMapper("trace_id"), spanCtx.TraceID().String()),
+			slog.String(e.keyMapper("span_id"), spanCtx.SpanID().String()),
 		}
-		if a.Time.Before(b.Time) {
-			return -1
+
+		if parent := span.Parent(); parent.IsValid() {
+			fields = append(fields, slog.String(e.keyMapper("parent_span_id"), parent.SpanID().String()))
 		}
-		return 1
-	})
 
-	for _, record := range records {
-		err := slog.Default().Handler().Handle(ctx, record)
-		if err != nil {
-			return err
+		fields = append(fields, slog.String(e.keyMapper("span_kind"), span.SpanKind().String()))
+
+		status := span.Status()
+		fields = append(fields, slog.String(e.keyMapper("status_code"), status.Code.String()))
+		if status.Description != "" {
+			fields = append(fields, slog.String(e.keyMapper("status_description"), status.Description))
+		}
+
+		scope := span.InstrumentationScope()
+		if scope.Name != "" {
+			fields = append(fields, slog.String(e.keyMapper("scope_name"), scope.Name))
 		}
+		if scope.Version != "" {
+			fields = append(fields, slog.String(e.keyMapper("scope_version"), scope.Version))
+		}
+
+		groups = append(groups, slog.Group("span", fields...))
 	}
 
-	return nil
+	if e.includeResourceAttributes && span.Resource() != nil {
+		if resourceAttrs := e.attributesToAttrs(span.Resource().Attributes()); len(resourceAttrs) > 0 {
+			fields := make([]any, len(resourceAttrs))
+			for i, attr := range resourceAttrs {
+				fields[i] = attr
+			}
+			groups = append(groups, slog.Group("resource", fields...))
+		}
+	}
+
+	return groups
 }
 
-func attributesToAttrs(attributes []attribute.KeyValue) []slog.Attr {
+func (e *Exporter) attributesToAttrs(attributes []attribute.KeyValue) []slog.Attr {
 	var attrs []slog.Attr
 
 	for _, attr := range attributes {
+		if e.attributeFilter != nil && !e.attributeFilter(attr) {
+			continue
+		}
+
 		key := string(attr.Key)
 
 		switch attr.Value.Type() {
@@ -95,19 +273,76 @@ func attributesToAttrs(attributes []attribute.KeyValue) []slog.Attr {
 		case attribute.STRING:
 			attrs = append(attrs, slog.String(key, attr.Value.AsString()))
 		case attribute.BOOLSLICE:
-			attrs = append(attrs, slog.String(key, fmt.Sprintf("%+v", attr.Value.AsBoolSlice())))
+			attrs = append(attrs, sliceAttr(e, key, attr.Value.AsBoolSlice(), slog.BoolValue))
 		case attribute.INT64SLICE:
-			attrs = append(attrs, slog.String(key, fmt.Sprintf("%+v", attr.Value.AsInt64Slice())))
+			attrs = append(attrs, sliceAttr(e, key, attr.Value.AsInt64Slice(), slog.Int64Value))
 		case attribute.FLOAT64SLICE:
-			attrs = append(attrs, slog.String(key, fmt.Sprintf("%+v", attr.Value.AsFloat64Slice())))
+			attrs = append(attrs, sliceAttr(e, key, attr.Value.AsFloat64Slice(), slog.Float64Value))
 		case attribute.STRINGSLICE:
-			attrs = append(attrs, slog.String(key, fmt.Sprintf("%+v", attr.Value.AsStringSlice())))
+			attrs = append(attrs, sliceAttr(e, key, attr.Value.AsStringSlice(), slog.StringValue))
 		}
 	}
 
 	return attrs
 }
 
+const exceptionAttrPrefix = "exception."
+
+// splitExceptionAttributes separates the OTel semantic exception attributes
+// (exception.type, exception.message, exception.stacktrace) from an event's
+// other attributes so they can be emitted as a nested "exception" group.
+func splitExceptionAttributes(attributes []attribute.KeyValue) (rest, exception []attribute.KeyValue) {
+	for _, attr := range attributes {
+		if strings.HasPrefix(string(attr.Key), exceptionAttrPrefix) {
+			exception = append(exception, attr)
+		} else {
+			rest = append(rest, attr)
+		}
+	}
+	return rest, exception
+}
+
+// exceptionGroupAttr builds a nested "exception" group from the semantic
+// exception attributes of an event, trimming the "exception." prefix from
+// each field's key. It reports false if no attributes survive filtering.
+func (e *Exporter) exceptionGroupAttr(attributes []attribute.KeyValue) (slog.Attr, bool) {
+	var fields []any
+
+	for _, attr := range attributes {
+		if e.attributeFilter != nil && !e.attributeFilter(attr) {
+			continue
+		}
+		if attr.Value.Type() != attribute.STRING {
+			continue
+		}
+		key := strings.TrimPrefix(string(attr.Key), exceptionAttrPrefix)
+		fields = append(fields, slog.String(key, attr.Value.AsString()))
+	}
+
+	if len(fields) == 0 {
+		return slog.Attr{}, false
+	}
+
+	return slog.Group("exception", fields...), true
+}
+
+// sliceAttr renders a slice-valued attribute according to the configured
+// ArrayEncoding.
+func sliceAttr[T any](e *Exporter, key string, values []T, toValue func(T) slog.Value) slog.Attr {
+	switch e.arrayEncoding {
+	case ArrayAsString:
+		return slog.String(key, fmt.Sprintf("%+v", values))
+	case ArrayAsGroup:
+		fields := make([]any, len(values))
+		for i, v := range values {
+			fields[i] = slog.Attr{Key: strconv.Itoa(i), Value: toValue(v)}
+		}
+		return slog.Group(key, fields...)
+	default:
+		return slog.Any(key, values)
+	}
+}
+
 func (e *Exporter) Shutdown(ctx context.Context) error {
 	e.stoppedMu.Lock()
 	e.stopped = true
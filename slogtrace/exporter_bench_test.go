@@ -0,0 +1,68 @@
+package slogtrace_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/subnova/slog-exporter/slogtrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/exp/slog"
+	"io"
+	"testing"
+)
+
+// spanCapturer is a sdktrace.SpanProcessor that records every span it sees
+// end, giving the benchmark real sdktrace.ReadOnlySpan values to export.
+type spanCapturer struct {
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (c *spanCapturer) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (c *spanCapturer) OnEnd(span sdktrace.ReadOnlySpan) {
+	c.spans = append(c.spans, span)
+}
+
+func (c *spanCapturer) Shutdown(context.Context) error { return nil }
+
+func (c *spanCapturer) ForceFlush(context.Context) error { return nil }
+
+func generateSpans(n int) []sdktrace.ReadOnlySpan {
+	capturer := &spanCapturer{}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(capturer))
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	tracer := tracerProvider.Tracer("bench")
+	for i := 0; i < n; i++ {
+		_, span := tracer.Start(context.Background(), "bench span")
+		span.AddEvent("bench event")
+		span.End()
+	}
+
+	return capturer.spans
+}
+
+func BenchmarkExportSpans(b *testing.B) {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+
+	for _, n := range []int{100, 1000, 10000} {
+		spans := generateSpans(n)
+
+		b.Run(fmt.Sprintf("%d spans", n), func(b *testing.B) {
+			exporter, err := slogtrace.New()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
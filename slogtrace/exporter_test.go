@@ -12,12 +12,13 @@ import (
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/slog"
+	"strings"
 	"testing"
 	"time"
 )
 
 func initLogProvider(filter attribute.Filter) (func(context.Context) error, error) {
-	traceExporter, err := slogtrace.New(filter)
+	traceExporter, err := slogtrace.New(slogtrace.WithAttributeFilter(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -154,20 +155,117 @@ func TestAttributesAreCorrectlyFormatted(t *testing.T) {
 	if data["string"] != "hello world" {
 		t.Errorf("expected string to be hello world, got %v", data["string"])
 	}
-	if data["bools"] != "[true false]" {
+	if bools, ok := data["bools"].([]any); !ok || bools[0] != true || bools[1] != false {
 		t.Errorf("expected bools to be [true, false], got %v", data["bools"])
 	}
-	if data["ints"] != "[1 1 2 3 5 8 13]" {
+	if ints, ok := data["ints"].([]any); !ok || ints[0] != 1.0 || ints[6] != 13.0 {
 		t.Errorf("expected ints to be [1 1 2 3 5 8 13], got %v", data["ints"])
 	}
-	if data["floats"] != "[3.14 2.71828]" {
+	if floats, ok := data["floats"].([]any); !ok || floats[0] != 3.14 || floats[1] != 2.71828 {
 		t.Errorf("expected floats to be [3.14 2.71828], got %v", data["floats"])
 	}
-	if data["strings"] != "[hello world]" {
+	if strs, ok := data["strings"].([]any); !ok || strs[0] != "hello" || strs[1] != "world" {
 		t.Errorf("expected strings to be [hello world], got %v", data["strings"])
 	}
 }
 
+func TestArrayEncodingAsString(t *testing.T) {
+	// setup slog to output JSON data
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer
+	traceExporter, err := slogtrace.New(slogtrace.WithArrayEncoding(slogtrace.ArrayAsString))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// emit a trace
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.StringSlice("strings", []string{"hello", "world"})))
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 1 {
+		t.Fatalf("expected 1 log line, got %d", len(logLines))
+	}
+
+	if logLines[0]["strings"] != "[hello world]" {
+		t.Errorf("expected strings to be [hello world], got %v", logLines[0]["strings"])
+	}
+}
+
+func TestArrayEncodingAsGroup(t *testing.T) {
+	// setup slog to output JSON data
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer
+	traceExporter, err := slogtrace.New(slogtrace.WithArrayEncoding(slogtrace.ArrayAsGroup))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// emit a trace
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.StringSlice("errors", []string{"error1", "error2"})))
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 1 {
+		t.Fatalf("expected 1 log line, got %d", len(logLines))
+	}
+
+	errors, ok := logLines[0]["errors"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected errors to be a group, got %v", logLines[0]["errors"])
+	}
+	if errors["0"] != "error1" {
+		t.Errorf("expected errors.0 to be error1, got %v", errors["0"])
+	}
+	if errors["1"] != "error2" {
+		t.Errorf("expected errors.1 to be error2, got %v", errors["1"])
+	}
+}
+
 func TestEventsAreEmitted(t *testing.T) {
 	// setup slog to output JSON data
 	buf := bytes.Buffer{}
@@ -311,3 +409,414 @@ func TestAttributesAreFiltered(t *testing.T) {
 		t.Errorf("expected string2 to be filtered, got %v", data["string2"])
 	}
 }
+
+func TestSpanContextIsEmitted(t *testing.T) {
+	// setup slog to output JSON data
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer
+	shutdown, err := initLogProvider(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = shutdown(context.Background())
+	}()
+
+	// emit a trace
+	tracer := otel.Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindServer))
+	sc := span.SpanContext()
+
+	_, child := tracer.Start(ctx, "test child", trace.WithSpanKind(trace.SpanKindInternal))
+	childSc := child.SpanContext()
+	child.End()
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(logLines))
+	}
+
+	rootSpanGroup, ok := logLines[0]["span"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected root log line to have a span group, got %v", logLines[0])
+	}
+	if rootSpanGroup["trace_id"] != sc.TraceID().String() {
+		t.Errorf("expected trace_id to be %v, got %v", sc.TraceID().String(), rootSpanGroup["trace_id"])
+	}
+	if rootSpanGroup["span_id"] != sc.SpanID().String() {
+		t.Errorf("expected span_id to be %v, got %v", sc.SpanID().String(), rootSpanGroup["span_id"])
+	}
+	if rootSpanGroup["parent_span_id"] != nil {
+		t.Errorf("expected root span to have no parent_span_id, got %v", rootSpanGroup["parent_span_id"])
+	}
+	if rootSpanGroup["span_kind"] != "server" {
+		t.Errorf("expected span_kind to be server, got %v", rootSpanGroup["span_kind"])
+	}
+
+	childSpanGroup, ok := logLines[1]["span"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected child log line to have a span group, got %v", logLines[1])
+	}
+	if childSpanGroup["span_id"] != childSc.SpanID().String() {
+		t.Errorf("expected span_id to be %v, got %v", childSc.SpanID().String(), childSpanGroup["span_id"])
+	}
+	if childSpanGroup["parent_span_id"] != sc.SpanID().String() {
+		t.Errorf("expected parent_span_id to be %v, got %v", sc.SpanID().String(), childSpanGroup["parent_span_id"])
+	}
+}
+
+func TestKeyMapperRenamesSpanFields(t *testing.T) {
+	// setup slog to output JSON data
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer with a custom key mapper
+	traceExporter, err := slogtrace.New(slogtrace.WithKeyMapper(func(key string) string {
+		return "dd." + key
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// emit a trace
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal))
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 1 {
+		t.Fatalf("expected 1 log line, got %d", len(logLines))
+	}
+
+	spanGroup, ok := logLines[0]["span"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected log line to have a span group, got %v", logLines[0])
+	}
+	if _, ok := spanGroup["dd.trace_id"]; !ok {
+		t.Errorf("expected dd.trace_id to be present, got %v", spanGroup)
+	}
+}
+
+func TestEventFilterSuppressesEvents(t *testing.T) {
+	// setup slog to output JSON data
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer
+	traceExporter, err := slogtrace.New(slogtrace.WithEventFilter(func(event sdktrace.Event) bool {
+		return event.Name != "noisy"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// emit a trace
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal))
+	span.AddEvent("noisy")
+	span.AddEvent("useful")
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(logLines))
+	}
+	if logLines[1]["msg"] != "useful" {
+		t.Errorf("expected noisy event to be suppressed, got %v", logLines[1]["msg"])
+	}
+}
+
+func TestEventLevelFuncDerivesLevel(t *testing.T) {
+	// setup slog to output JSON data
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer
+	traceExporter, err := slogtrace.New(slogtrace.WithEventLevelFunc(func(event sdktrace.Event) slog.Level {
+		if strings.HasPrefix(event.Name, "exception") {
+			return slog.LevelError
+		}
+		if event.Name == "debug-only" {
+			return slogtrace.LevelSkip
+		}
+		return slog.LevelInfo
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// emit a trace
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal))
+	span.AddEvent("debug-only")
+	span.AddEvent("exception", trace.WithAttributes(
+		attribute.String("exception.type", "*errors.errorString"),
+		attribute.String("exception.message", "boom")))
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(logLines))
+	}
+	if logLines[1]["level"] != "ERROR" {
+		t.Errorf("expected exception event to be ERROR, got %v", logLines[1]["level"])
+	}
+
+	exceptionGroup, ok := logLines[1]["exception"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected exception event to have an exception group, got %v", logLines[1])
+	}
+	if exceptionGroup["type"] != "*errors.errorString" {
+		t.Errorf("expected exception.type to be *errors.errorString, got %v", exceptionGroup["type"])
+	}
+	if exceptionGroup["message"] != "boom" {
+		t.Errorf("expected exception.message to be boom, got %v", exceptionGroup["message"])
+	}
+}
+
+func TestEventLevelBelowHandlerThresholdIsNotEmitted(t *testing.T) {
+	// setup slog to output JSON data, rejecting anything below Info
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer with an event level func that derives a level below
+	// the handler's threshold
+	traceExporter, err := slogtrace.New(slogtrace.WithEventLevelFunc(func(event sdktrace.Event) slog.Level {
+		return slog.LevelDebug
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// emit a trace
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal))
+	span.AddEvent("debug event")
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 1 {
+		t.Fatalf("expected the debug event to be dropped by the handler's level threshold, got %d log lines", len(logLines))
+	}
+	if logLines[0]["msg"] != "test" {
+		t.Errorf("expected the remaining log line to be the span itself, got %v", logLines[0]["msg"])
+	}
+}
+
+func TestUnorderedStillEmitsEveryRecord(t *testing.T) {
+	// setup slog to output JSON data
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	// initialize tracer
+	traceExporter, err := slogtrace.New(slogtrace.WithOrdering(slogtrace.Unordered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	// emit some traces
+	tracer := otel.Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal))
+	_, span2 := tracer.Start(ctx, "test2", trace.WithSpanKind(trace.SpanKindInternal))
+	span2.End()
+	span.End()
+
+	// flush the buffer
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	// check the output
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logLines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(logLines))
+	}
+}
+
+func TestWithHandlerSendsRecordsToGivenHandler(t *testing.T) {
+	// default slog output, which should receive nothing
+	defaultBuf := bytes.Buffer{}
+	defaultW := bufio.NewWriter(&defaultBuf)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(defaultW, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	// dedicated handler the exporter should use instead
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+
+	traceExporter, err := slogtrace.New(slogtrace.WithHandler(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal))
+	span.End()
+
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+	_ = defaultW.Flush()
+
+	if defaultBuf.Len() != 0 {
+		t.Errorf("expected slog.Default() to receive nothing, got %q", defaultBuf.String())
+	}
+
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logLines) != 1 {
+		t.Fatalf("expected 1 log line on the dedicated handler, got %d", len(logLines))
+	}
+}
+
+func TestWithBaseAttrsAddedToEveryRecord(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(h))
+
+	traceExporter, err := slogtrace.New(slogtrace.WithBaseAttrs([]slog.Attr{
+		slog.String("service.name", "checkout"),
+		slog.String("env", "staging"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(traceExporter, sdktrace.WithBatchTimeout(100*time.Millisecond), sdktrace.WithExportTimeout(100*time.Millisecond)))
+	otel.SetTracerProvider(tracerProvider)
+	defer func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	}()
+
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "test", trace.WithSpanKind(trace.SpanKindInternal))
+	span.AddEvent("event")
+	span.End()
+
+	time.Sleep(200 * time.Millisecond)
+	_ = w.Flush()
+
+	logLines, err := parseLogLines(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logLines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(logLines))
+	}
+	for _, line := range logLines {
+		if line["service.name"] != "checkout" {
+			t.Errorf("expected service.name to be checkout, got %v", line["service.name"])
+		}
+		if line["env"] != "staging" {
+			t.Errorf("expected env to be staging, got %v", line["env"])
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package slogtrace
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/exp/slog"
+)
+
+// KeyMapper renames the canonical field name used for a span-intrinsic
+// field (trace_id, span_id, span_kind, ...) before it is written to the
+// "span" group. It does not affect resource or regular span/event
+// attribute keys, which are emitted as-is. The default mapper is the
+// identity function, which emits OTel semantic-convention style keys
+// unchanged.
+type KeyMapper func(string) string
+
+// Option configures an Exporter. Options are applied in the order they are
+// passed to New.
+type Option func(*Exporter)
+
+// WithKeyMapper overrides the naming convention used for span-intrinsic
+// fields (trace_id, span_id, span_kind, ...). It defaults to the identity
+// function.
+func WithKeyMapper(mapper KeyMapper) Option {
+	return func(e *Exporter) {
+		e.keyMapper = mapper
+	}
+}
+
+// WithResourceAttributes controls whether the span's resource attributes
+// (e.g. service.name) are emitted as a nested "resource" group. Defaults to
+// true.
+func WithResourceAttributes(include bool) Option {
+	return func(e *Exporter) {
+		e.includeResourceAttributes = include
+	}
+}
+
+// WithIncludeSpanContext controls whether trace_id, span_id,
+// parent_span_id, span_kind, status_code, status_description, and
+// instrumentation scope fields are emitted as a nested "span" group.
+// Defaults to true.
+func WithIncludeSpanContext(include bool) Option {
+	return func(e *Exporter) {
+		e.includeSpanContext = include
+	}
+}
+
+// WithAttributeFilter restricts which span and event attributes are
+// emitted. Attributes for which filter returns false are dropped. A nil
+// filter (the default) emits every attribute.
+func WithAttributeFilter(filter attribute.Filter) Option {
+	return func(e *Exporter) {
+		e.attributeFilter = filter
+	}
+}
+
+func defaultKeyMapper(key string) string {
+	return key
+}
+
+// ArrayEncoding controls how slice-valued attributes (BoolSlice, IntSlice,
+// Float64Slice, StringSlice) are rendered.
+type ArrayEncoding int
+
+const (
+	// ArrayAsJSON emits the slice as a native slog value, so JSON handlers
+	// render it as a real JSON array. This is the default.
+	ArrayAsJSON ArrayEncoding = iota
+	// ArrayAsString emits the slice formatted as a single string, matching
+	// this package's historical behaviour.
+	ArrayAsString
+	// ArrayAsGroup emits each element as an indexed sub-attribute nested
+	// under the slice's key.
+	ArrayAsGroup
+)
+
+// WithArrayEncoding overrides how slice-valued attributes are rendered.
+// Defaults to ArrayAsJSON.
+func WithArrayEncoding(encoding ArrayEncoding) Option {
+	return func(e *Exporter) {
+		e.arrayEncoding = encoding
+	}
+}
+
+// EventLevelFunc derives the slog.Level for a span event, instead of
+// inheriting the level of its parent span. Returning LevelSkip drops the
+// event entirely.
+type EventLevelFunc func(sdktrace.Event) slog.Level
+
+// LevelSkip is a sentinel level returned from an EventLevelFunc to drop an
+// event rather than emit it.
+const LevelSkip = slog.Level(1 << 30)
+
+// WithEventLevelFunc sets the function used to derive a span event's level.
+// By default every event is emitted at the same level as its parent span.
+func WithEventLevelFunc(fn EventLevelFunc) Option {
+	return func(e *Exporter) {
+		e.eventLevelFunc = fn
+	}
+}
+
+// WithEventFilter suppresses span events for which filter returns false.
+// By default every event is emitted.
+func WithEventFilter(filter func(sdktrace.Event) bool) Option {
+	return func(e *Exporter) {
+		e.eventFilter = filter
+	}
+}
+
+// Ordering controls whether ExportSpans time-orders records across the
+// spans in a batch.
+type Ordering int
+
+const (
+	// Ordered merges every span's records into a single time-ordered
+	// stream across the whole batch. This is the default.
+	Ordered Ordering = iota
+	// Unordered emits each span's records (in their own time order) as
+	// soon as they're built, without merging across spans. This skips the
+	// merge step entirely, trading cross-span ordering for throughput.
+	Unordered
+)
+
+// WithOrdering overrides whether records are time-ordered across the spans
+// in a batch. Defaults to Ordered.
+func WithOrdering(ordering Ordering) Option {
+	return func(e *Exporter) {
+		e.ordering = ordering
+	}
+}
+
+// WithLogger sends exported records to logger's handler instead of
+// slog.Default(). WithHandler takes precedence if both are set.
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Exporter) {
+		e.logger = logger
+	}
+}
+
+// WithHandler sends exported records directly to handler instead of
+// slog.Default(). Takes precedence over WithLogger.
+func WithHandler(handler slog.Handler) Option {
+	return func(e *Exporter) {
+		e.handler = handler
+	}
+}
+
+// WithBaseAttrs attaches static attributes, such as service name,
+// environment, or version, to every record the Exporter emits.
+func WithBaseAttrs(attrs []slog.Attr) Option {
+	return func(e *Exporter) {
+		e.baseAttrs = attrs
+	}
+}